@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -12,18 +13,29 @@ import (
 	"github.com/aspiration-labs/pyggpot/internal/models"
 
 	"github.com/aspiration-labs/pyggpot/internal/hooks"
+	admin_provider "github.com/aspiration-labs/pyggpot/internal/providers/admin"
 	coin_provider "github.com/aspiration-labs/pyggpot/internal/providers/coin"
+	eventlog_provider "github.com/aspiration-labs/pyggpot/internal/providers/eventlog"
 	pot_provider "github.com/aspiration-labs/pyggpot/internal/providers/pot"
+	sidecar_provider "github.com/aspiration-labs/pyggpot/internal/providers/sidecar"
+	snapshot_provider "github.com/aspiration-labs/pyggpot/internal/providers/snapshot"
+	admin_service "github.com/aspiration-labs/pyggpot/rpc/go/admin"
 	coin_service "github.com/aspiration-labs/pyggpot/rpc/go/coin"
+	eventlog_service "github.com/aspiration-labs/pyggpot/rpc/go/eventlog"
 	pot_service "github.com/aspiration-labs/pyggpot/rpc/go/pot"
+	sidecar_service "github.com/aspiration-labs/pyggpot/rpc/go/sidecar"
+	snapshot_service "github.com/aspiration-labs/pyggpot/rpc/go/snapshot"
 	_ "github.com/aspiration-labs/pyggpot/swaggerui-statik/statik"
 	"github.com/gorilla/mux"
 	"github.com/rakyll/statik/fs"
+	"github.com/twitchtv/twirp"
 	"github.com/xo/dburl"
 )
 
 var flagVerbose = flag.Bool("v", false, "verbose")
 var flagDB = flag.String("url", "file:database.sqlite3?_loc=auto&_foreign_keys=1", "database url")
+var flagServerID = flag.String("server-id", "", "identifier stamped into exported snapshots; defaults to hostname")
+var flagAuthToken = flag.String("auth-token", "", "bearer token required on every RPC; auth and rate limiting are disabled if empty")
 
 func main() {
 	rand.Seed(time.Now().UnixNano())
@@ -49,11 +61,81 @@ func main() {
 	staticServer := http.FileServer(statikFS)
 	router.PathPrefix("/swaggerui/").Handler(http.StripPrefix("/swaggerui/", staticServer))
 
-	hook := hooks.LoggingHooks(os.Stderr)
+	// twirp.HTTPRequestHeaders only returns headers stored in the context, so
+	// copy the inbound ones there for AuthHooks and the coin provider to read.
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := r.Header.Clone()
+			for _, reserved := range []string{"Accept", "Content-Type", "Twirp-Version"} {
+				h.Del(reserved)
+			}
+			if ctx, err := twirp.WithHTTPRequestHeaders(r.Context(), h); err == nil {
+				r = r.WithContext(ctx)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	loggingHook := hooks.LoggingHooks(os.Stderr)
+	adminProvider := admin_provider.New(db)
+	haltHook := hooks.HaltHooks(adminProvider, map[string]bool{
+		"ListCoins":      true,
+		"ListPots":       true,
+		"GetPot":         true,
+		"ListEvents":     true,
+		"ExportSnapshot": true,
+		"GetSidecars":    true,
+		// Admin's own RPCs must stay reachable through a halt, or there is
+		// no way to inspect or lift one once SetHalt takes effect short of
+		// editing the halts table directly.
+		"SetHalt":   true,
+		"ClearHalt": true,
+		"GetHalt":   true,
+	})
+	hook := twirp.ChainHooks(loggingHook, haltHook)
+	var limiter *hooks.RateLimiter
+	if *flagAuthToken != "" {
+		authHook := hooks.AuthHooks(hooks.StaticTokenValidator(map[string]string{*flagAuthToken: "default"}))
+
+		limiter = hooks.NewRateLimiter(db, map[string]hooks.RateLimitConfig{
+			"RemoveCoins": {Capacity: 5, RefillPerSecond: 0.5},
+			"AddCoins":    {Capacity: 20, RefillPerSecond: 5},
+		}, hooks.RateLimitConfig{Capacity: 50, RefillPerSecond: 10}, 10000)
+		flushCtx, stopFlush := context.WithCancel(context.Background())
+		defer stopFlush()
+		go limiter.RunFlushLoop(flushCtx, 30*time.Second)
+
+		rateLimitHook := hooks.RateLimitHooks(limiter)
+		hook = twirp.ChainHooks(hook, authHook, rateLimitHook)
+	}
+
+	adminServer := admin_service.NewAdminServer(adminProvider, hook)
+	router.PathPrefix(admin_service.AdminPathPrefix).Handler(adminServer)
+	eventLogProvider := eventlog_provider.New(db)
+	eventLogServer := eventlog_service.NewEventLogServer(eventLogProvider, hook)
+	router.PathPrefix(eventlog_service.EventLogPathPrefix).Handler(eventLogServer)
+
+	serverID := *flagServerID
+	if serverID == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			serverID = hostname
+		}
+	}
+	snapshotProvider := snapshot_provider.New(db, serverID)
+	snapshotServer := snapshot_service.NewSnapshotServer(snapshotProvider, hook)
+	router.PathPrefix(snapshot_service.SnapshotPathPrefix).Handler(snapshotServer)
+	sidecarProvider := sidecar_provider.New(db)
+	sidecarServer := sidecar_service.NewSidecarServer(sidecarProvider, hook)
+	router.PathPrefix(sidecar_service.SidecarPathPrefix).Handler(sidecarServer)
+
 	potProvider := pot_provider.New(db)
 	potServer := pot_service.NewPotServer(potProvider, hook)
 	router.PathPrefix(pot_service.PotPathPrefix).Handler(potServer)
-	coinProvider := coin_provider.New(db)
+	coinOpts := []coin_provider.Option{coin_provider.WithRandSource(rand.NewSource(time.Now().UnixNano()))}
+	if limiter != nil {
+		coinOpts = append(coinOpts, coin_provider.WithRateLimiter(limiter))
+	}
+	coinProvider := coin_provider.New(db, coinOpts...)
 	coinServer := coin_service.NewCoinServer(coinProvider, hook)
 	router.PathPrefix(coin_service.CoinPathPrefix).Handler(coinServer)
 	log.Fatal(http.ListenAndServe(":8080", router))
@@ -0,0 +1,57 @@
+package hooks
+
+import (
+	"context"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+)
+
+// identityContextKey is how AuthHooks passes the authenticated caller
+// identity forward to later hooks (notably RateLimitHooks) and to provider
+// code, via the context twirp threads through the hook chain.
+type identityContextKey struct{}
+
+// Identity returns the caller identity AuthHooks attached to ctx, or ""
+// if no AuthHooks ran (or the call was anonymous and allowed to be).
+func Identity(ctx context.Context) string {
+	id, _ := ctx.Value(identityContextKey{}).(string)
+	return id
+}
+
+// TokenValidator maps a bearer token to a caller identity. Returning ok=false
+// rejects the request with twirp.Unauthenticated.
+type TokenValidator func(token string) (identity string, ok bool)
+
+// StaticTokenValidator builds a TokenValidator from a fixed token->identity
+// map, suitable for tokens loaded once from a config file at startup.
+func StaticTokenValidator(tokens map[string]string) TokenValidator {
+	return func(token string) (string, bool) {
+		identity, ok := tokens[token]
+		return identity, ok
+	}
+}
+
+// AuthHooks builds ServerHooks that require a `Bearer <token>` Authorization
+// header on every RPC, resolve it to a caller identity via validate, and
+// attach that identity to the context for RateLimitHooks and provider code
+// to read back via Identity(ctx).
+func AuthHooks(validate TokenValidator) *twirp.ServerHooks {
+	return &twirp.ServerHooks{
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			headers, ok := twirp.HTTPRequestHeaders(ctx)
+			if !ok {
+				return ctx, twirp.NewError(twirp.Unauthenticated, "missing authorization header")
+			}
+			token := strings.TrimPrefix(headers.Get("Authorization"), "Bearer ")
+			if token == "" {
+				return ctx, twirp.NewError(twirp.Unauthenticated, "missing authorization header")
+			}
+			identity, ok := validate(token)
+			if !ok {
+				return ctx, twirp.NewError(twirp.Unauthenticated, "invalid bearer token")
+			}
+			return context.WithValue(ctx, identityContextKey{}, identity), nil
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package hooks
+
+import (
+	"context"
+
+	"github.com/twitchtv/twirp"
+)
+
+// HaltChecker reports whether mutating RPCs should currently be refused.
+// internal/providers/admin's provider implements this directly against the
+// halts table so HaltHooks doesn't need to depend on the admin RPC types.
+type HaltChecker interface {
+	IsHalted(ctx context.Context) (bool, error)
+}
+
+// HaltHooks builds ServerHooks that reject every RPC whose method name is
+// not in readOnlyMethods while a halt is active, returning twirp.Unavailable.
+// New mutating RPCs are halted by default as soon as they're added to a
+// service; a method only stays reachable during a halt by being explicitly
+// listed, which is deliberately the safer direction to get wrong.
+func HaltHooks(checker HaltChecker, readOnlyMethods map[string]bool) *twirp.ServerHooks {
+	return &twirp.ServerHooks{
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			method, _ := twirp.MethodName(ctx)
+			if readOnlyMethods[method] {
+				return ctx, nil
+			}
+			halted, err := checker.IsHalted(ctx)
+			if err != nil {
+				return ctx, twirp.InternalError(err.Error())
+			}
+			if halted {
+				return ctx, twirp.NewError(twirp.Unavailable, "pot activity is halted for maintenance")
+			}
+			return ctx, nil
+		},
+	}
+}
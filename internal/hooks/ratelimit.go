@@ -0,0 +1,220 @@
+package hooks
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/aspiration-labs/pyggpot/internal/models"
+	"github.com/twitchtv/twirp"
+)
+
+// RateLimitConfig is the token-bucket shape for one RPC method: it can hold
+// up to Capacity tokens, refilling at RefillPerSecond, and each call costs
+// one token.
+type RateLimitConfig struct {
+	Capacity        float64
+	RefillPerSecond float64
+}
+
+type bucketKey struct {
+	identity string
+	potID    int32
+	method   string
+}
+
+type bucket struct {
+	key        bucketKey
+	tokens     float64
+	lastRefill time.Time
+	elem       *list.Element
+}
+
+// RateLimiter enforces RateLimitConfig per (identity, pot_id, method),
+// keeping live bucket state in a bounded in-memory LRU and periodically
+// flushing token counts to the rate_limits table so they survive a
+// restart approximately. RemoveCoins in particular is cheap to spam and
+// expensive to serve (transactional read + N updates + delete), which is
+// the DoS surface this closes.
+type RateLimiter struct {
+	db       *sql.DB
+	defaults map[string]RateLimitConfig
+	fallback RateLimitConfig
+
+	mu         sync.Mutex
+	buckets    map[bucketKey]*bucket
+	lru        *list.List
+	maxBuckets int
+}
+
+// NewRateLimiter builds a RateLimiter. defaults configures specific RPC
+// methods; any method not listed falls back to fallback. maxBuckets bounds
+// memory use under a large number of distinct (identity, pot_id) pairs;
+// the least-recently-used bucket is evicted once the cache is full, without
+// being flushed first.
+func NewRateLimiter(db *sql.DB, defaults map[string]RateLimitConfig, fallback RateLimitConfig, maxBuckets int) *RateLimiter {
+	return &RateLimiter{
+		db:         db,
+		defaults:   defaults,
+		fallback:   fallback,
+		buckets:    make(map[bucketKey]*bucket),
+		lru:        list.New(),
+		maxBuckets: maxBuckets,
+	}
+}
+
+func (l *RateLimiter) configFor(method string) RateLimitConfig {
+	if cfg, ok := l.defaults[method]; ok {
+		return cfg
+	}
+	return l.fallback
+}
+
+// Allow consumes one token from the (identity, pot_id, method) bucket,
+// refilling it for elapsed time first, and reports whether the call may
+// proceed.
+func (l *RateLimiter) Allow(identity string, potID int32, method string) bool {
+	key := bucketKey{identity: identity, potID: potID, method: method}
+	cfg := l.configFor(method)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = l.restore(key, cfg, now)
+		l.buckets[key] = b
+		b.elem = l.lru.PushFront(b)
+		l.evictLocked()
+	} else {
+		l.lru.MoveToFront(b.elem)
+	}
+
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens += elapsed * cfg.RefillPerSecond
+	if b.tokens > cfg.Capacity {
+		b.tokens = cfg.Capacity
+	}
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// restore loads a bucket's persisted token count so limits survive a
+// restart approximately, defaulting to a full bucket if nothing was ever
+// flushed for this key (or the flush just hasn't happened yet).
+func (l *RateLimiter) restore(key bucketKey, cfg RateLimitConfig, now time.Time) *bucket {
+	b := &bucket{key: key, tokens: cfg.Capacity, lastRefill: now}
+	if l.db == nil {
+		return b
+	}
+	tx, err := l.db.Begin()
+	if err != nil {
+		return b
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	state, err := models.RateLimitStateByKey(tx, key.identity, key.potID, key.method)
+	if err != nil || state == nil {
+		return b
+	}
+	b.tokens = state.Tokens
+	b.lastRefill = time.Unix(state.UpdatedAt, 0)
+	return b
+}
+
+// evictLocked drops the least-recently-used bucket once maxBuckets is
+// exceeded. Its last-known token count is lost until the next restore reads
+// whatever was last flushed, which is the "approximately" in this package's
+// restart-survival guarantee.
+func (l *RateLimiter) evictLocked() {
+	if l.maxBuckets <= 0 || l.lru.Len() <= l.maxBuckets {
+		return
+	}
+	oldest := l.lru.Back()
+	if oldest == nil {
+		return
+	}
+	l.lru.Remove(oldest)
+	delete(l.buckets, oldest.Value.(*bucket).key)
+}
+
+// Flush writes every in-memory bucket's current token count to the
+// rate_limits table. Call it on a ticker (see RunFlushLoop) from main.go.
+func (l *RateLimiter) Flush() error {
+	l.mu.Lock()
+	snapshot := make([]*bucket, 0, len(l.buckets))
+	for _, b := range l.buckets {
+		bCopy := *b
+		snapshot = append(snapshot, &bCopy)
+	}
+	l.mu.Unlock()
+
+	if l.db == nil || len(snapshot) == 0 {
+		return nil
+	}
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, b := range snapshot {
+		state := &models.RateLimitState{
+			Identity:  b.key.identity,
+			PotID:     b.key.potID,
+			Method:    b.key.method,
+			Tokens:    b.tokens,
+			UpdatedAt: b.lastRefill.Unix(),
+		}
+		if err := state.Save(tx); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RunFlushLoop calls Flush on interval until ctx is done. main.go starts
+// this in its own goroutine alongside the HTTP server.
+func (l *RateLimiter) RunFlushLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = l.Flush()
+		}
+	}
+}
+
+// RateLimitHooks builds ServerHooks that reject a call with
+// twirp.ResourceExhausted once its (identity, method) bucket runs dry.
+// Identity comes from AuthHooks via Identity(ctx), so this is keyed off the
+// authenticated caller rather than anything the caller can vary at will.
+//
+// This only covers the generic, pot-agnostic case: ServerHooks run before
+// the request body is decoded, so a per-pot limit for RPCs like
+// RemoveCoins/AddCoins (where the cost of serving the call scales with the
+// pot, and where a caller could otherwise spam a single pot without it
+// showing up here) is enforced separately, in the provider, once PotId has
+// actually been decoded - see coin_provider's use of RateLimiter.Allow.
+func RateLimitHooks(limiter *RateLimiter) *twirp.ServerHooks {
+	return &twirp.ServerHooks{
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			method, _ := twirp.MethodName(ctx)
+			identity := Identity(ctx)
+			if !limiter.Allow(identity, 0, method) {
+				return ctx, twirp.NewError(twirp.ResourceExhausted, "rate limit exceeded")
+			}
+			return ctx, nil
+		},
+	}
+}
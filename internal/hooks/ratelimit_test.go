@@ -0,0 +1,82 @@
+package hooks
+
+import "testing"
+
+func newTestLimiter(defaults map[string]RateLimitConfig, fallback RateLimitConfig) *RateLimiter {
+	return NewRateLimiter(nil, defaults, fallback, 10000)
+}
+
+func TestRateLimiterAllowsUpToCapacity(t *testing.T) {
+	limiter := newTestLimiter(nil, RateLimitConfig{Capacity: 3, RefillPerSecond: 0})
+
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("alice", 1, "RemoveCoins") {
+			t.Fatalf("call %d: expected Allow to succeed within capacity", i)
+		}
+	}
+	if limiter.Allow("alice", 1, "RemoveCoins") {
+		t.Fatal("expected Allow to fail once the bucket is exactly empty")
+	}
+}
+
+func TestRateLimiterIsolatesBucketsByKey(t *testing.T) {
+	limiter := newTestLimiter(nil, RateLimitConfig{Capacity: 1, RefillPerSecond: 0})
+
+	if !limiter.Allow("alice", 1, "RemoveCoins") {
+		t.Fatal("expected first call for alice/pot 1 to succeed")
+	}
+	if limiter.Allow("alice", 1, "RemoveCoins") {
+		t.Fatal("expected alice/pot 1 to be out of tokens")
+	}
+	if !limiter.Allow("alice", 2, "RemoveCoins") {
+		t.Fatal("expected alice/pot 2 to have its own, untouched bucket")
+	}
+	if !limiter.Allow("bob", 1, "RemoveCoins") {
+		t.Fatal("expected bob/pot 1 to have its own, untouched bucket")
+	}
+	if !limiter.Allow("alice", 1, "AddCoins") {
+		t.Fatal("expected alice/pot 1's AddCoins bucket to be independent of its RemoveCoins bucket")
+	}
+}
+
+func TestRateLimiterPerMethodConfig(t *testing.T) {
+	limiter := newTestLimiter(map[string]RateLimitConfig{
+		"RemoveCoins": {Capacity: 1, RefillPerSecond: 0},
+	}, RateLimitConfig{Capacity: 5, RefillPerSecond: 0})
+
+	if !limiter.Allow("alice", 1, "RemoveCoins") {
+		t.Fatal("expected first RemoveCoins call to succeed")
+	}
+	if limiter.Allow("alice", 1, "RemoveCoins") {
+		t.Fatal("RemoveCoins has capacity 1, second call should be rejected")
+	}
+	for i := 0; i < 5; i++ {
+		if !limiter.Allow("alice", 1, "ListPots") {
+			t.Fatalf("call %d: ListPots should fall back to the 5-capacity default", i)
+		}
+	}
+	if limiter.Allow("alice", 1, "ListPots") {
+		t.Fatal("expected the fallback bucket to be empty after 5 calls")
+	}
+}
+
+func TestRateLimiterEvictsLeastRecentlyUsed(t *testing.T) {
+	limiter := newTestLimiter(nil, RateLimitConfig{Capacity: 1, RefillPerSecond: 0})
+	limiter.maxBuckets = 2
+
+	limiter.Allow("alice", 1, "RemoveCoins") // bucket A, now empty
+	limiter.Allow("bob", 1, "RemoveCoins")   // bucket B, now empty
+	limiter.Allow("carol", 1, "RemoveCoins") // bucket C, now empty; evicts A (oldest)
+
+	if len(limiter.buckets) != 2 {
+		t.Fatalf("expected 2 buckets after eviction, got %d", len(limiter.buckets))
+	}
+	if _, ok := limiter.buckets[bucketKey{identity: "alice", potID: 1, method: "RemoveCoins"}]; ok {
+		t.Fatal("expected alice's bucket to have been evicted as least recently used")
+	}
+	// A fresh bucket under the same key starts full again, independent of
+	// the evicted bucket's prior (empty) state.
+	if !limiter.Allow("alice", 1, "RemoveCoins") {
+		t.Fatal("expected alice's re-created bucket to start with a full capacity of 1")
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql"
+)
+
+// CoinEvent is an immutable, append-only record of a single coin movement.
+// AddCoins and RemoveCoins insert one per denomination touched, in the same
+// transaction as the CoinsInPot update, so a coin movement is never committed
+// without its event. ImportSnapshot replaces a pot's coin rows without
+// writing events, so replaying from id 0 reconstructs a pot's state at any
+// point in time only for pots that have never had a snapshot imported.
+type CoinEvent struct {
+	ID        int32  `json:"id"`
+	PotID     int32  `json:"pot_id"`
+	Kind      int32  `json:"kind"`
+	Delta     int32  `json:"delta"` // positive for AddCoins, negative for RemoveCoins
+	Actor     string `json:"actor"`
+	RequestID string `json:"request_id"`
+	CreatedAt int64  `json:"created_at"` // unix seconds
+}
+
+// Save inserts the event. CoinEvents are never updated or deleted, so unlike
+// the other models in this package there is no update branch here.
+func (e *CoinEvent) Save(tx *sql.Tx) error {
+	res, err := tx.Exec(
+		`INSERT INTO coin_events (pot_id, kind, delta, actor, request_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		e.PotID, e.Kind, e.Delta, e.Actor, e.RequestID, e.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	e.ID = int32(id)
+	return nil
+}
+
+// CoinEventsByPot_idSince returns pot_id's events with id > sinceID, in id
+// order, for ListEvents callers to tail the log. Named to match this
+// package's CoinsInPotsByPot_id convention for query-derived lookups.
+func CoinEventsByPot_idSince(tx *sql.Tx, potID int, sinceID int) ([]*CoinEvent, error) {
+	rows, err := tx.Query(
+		`SELECT id, pot_id, kind, delta, actor, request_id, created_at FROM coin_events WHERE pot_id = ? AND id > ? ORDER BY id ASC`,
+		potID, sinceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*CoinEvent
+	for rows.Next() {
+		e := &CoinEvent{}
+		if err := rows.Scan(&e.ID, &e.PotID, &e.Kind, &e.Delta, &e.Actor, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// LatestCoinEventID returns the highest coin_events id for potID, or 0 if
+// the pot has no events yet. ExportSnapshot uses this as the event cursor
+// when the caller doesn't pin one explicitly.
+func LatestCoinEventID(tx *sql.Tx, potID int) (int32, error) {
+	var id sql.NullInt64
+	err := tx.QueryRow(`SELECT MAX(id) FROM coin_events WHERE pot_id = ?`, potID).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return int32(id.Int64), nil
+}
+
+// CoinEventExistsByRequestID reports whether an event with this request ID
+// has already been committed, so AddCoins/RemoveCoins can reject retries of
+// the same request instead of double-applying them.
+func CoinEventExistsByRequestID(tx *sql.Tx, requestID string) (bool, error) {
+	if requestID == "" {
+		return false, nil
+	}
+	var exists bool
+	err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM coin_events WHERE request_id = ?)`, requestID).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// CoinSidecar carries the optional, rarely-needed attributes of a coin row
+// (mint year, provenance note, serial numbers for collectibles) in a table
+// of its own, keyed by the owning Coin's row ID. Keeping it out of Coin
+// means shakePot and a plain ListCoins never pay for a join they didn't ask
+// for.
+type CoinSidecar struct {
+	CoinID        int32  `json:"coin_id"`
+	MintYear      int32  `json:"mint_year"`
+	Provenance    string `json:"provenance"`
+	SerialNumbers string `json:"serial_numbers"` // JSON-encoded []string
+}
+
+// Save inserts or updates the sidecar row for CoinID.
+func (c *CoinSidecar) Save(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		`INSERT INTO coin_sidecar (coin_id, mint_year, provenance, serial_numbers) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(coin_id) DO UPDATE SET mint_year = excluded.mint_year, provenance = excluded.provenance, serial_numbers = excluded.serial_numbers`,
+		c.CoinID, c.MintYear, c.Provenance, c.SerialNumbers,
+	)
+	return err
+}
+
+// Delete removes the sidecar row for CoinID, if any.
+func (c *CoinSidecar) Delete(tx *sql.Tx) error {
+	_, err := tx.Exec(`DELETE FROM coin_sidecar WHERE coin_id = ?`, c.CoinID)
+	return err
+}
+
+// CoinSidecarsByCoin_ids loads sidecars for coinIDs in one query, keyed by
+// coin ID, so callers that want sidecar data for a batch of coins (e.g. a
+// GetSidecars RPC) can join lazily instead of always carrying the cost.
+// Named to match this package's CoinsInPotsByPot_id convention.
+func CoinSidecarsByCoin_ids(tx *sql.Tx, coinIDs []int32) (map[int32]*CoinSidecar, error) {
+	result := make(map[int32]*CoinSidecar, len(coinIDs))
+	if len(coinIDs) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, len(coinIDs))
+	placeholders := ""
+	for i, id := range coinIDs {
+		if i > 0 {
+			placeholders += ", "
+		}
+		placeholders += "?"
+		args[i] = id
+	}
+
+	rows, err := tx.Query(
+		`SELECT coin_id, mint_year, provenance, serial_numbers FROM coin_sidecar WHERE coin_id IN (`+placeholders+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := &CoinSidecar{}
+		if err := rows.Scan(&c.CoinID, &c.MintYear, &c.Provenance, &c.SerialNumbers); err != nil {
+			return nil, err
+		}
+		result[c.CoinID] = c
+	}
+	return result, rows.Err()
+}
+
+// MarshalSerialNumbers is a small convenience so callers don't each
+// reimplement JSON-encoding the serial number list before a Save.
+func MarshalSerialNumbers(serials []string) (string, error) {
+	b, err := json.Marshal(serials)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// UnmarshalSerialNumbers is the inverse of MarshalSerialNumbers.
+func UnmarshalSerialNumbers(serialized string) ([]string, error) {
+	if serialized == "" {
+		return nil, nil
+	}
+	var serials []string
+	if err := json.Unmarshal([]byte(serialized), &serials); err != nil {
+		return nil, err
+	}
+	return serials, nil
+}
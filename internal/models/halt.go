@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql"
+)
+
+// Halt records a maintenance-mode window during which mutating pot/coin RPCs
+// should be refused. Only one halt is ever active at a time: setting a new
+// one supersedes whatever was active before, and clearing it stamps
+// ClearedAt rather than deleting the row, so the halts table doubles as an
+// audit log of past incidents and migrations.
+type Halt struct {
+	ID        int32         `json:"id"`
+	Reason    string        `json:"reason"`
+	HaltedAt  int64         `json:"halted_at"`  // unix seconds the halt takes effect
+	ClearedAt sql.NullInt64 `json:"cleared_at"` // unix seconds the halt was lifted, if any
+}
+
+// Save inserts h if it has no ID yet, or updates the existing row otherwise.
+func (h *Halt) Save(tx *sql.Tx) error {
+	if h.ID == 0 {
+		res, err := tx.Exec(`INSERT INTO halts (reason, halted_at, cleared_at) VALUES (?, ?, ?)`,
+			h.Reason, h.HaltedAt, h.ClearedAt)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		h.ID = int32(id)
+		return nil
+	}
+	_, err := tx.Exec(`UPDATE halts SET reason = ?, halted_at = ?, cleared_at = ? WHERE id = ?`,
+		h.Reason, h.HaltedAt, h.ClearedAt, h.ID)
+	return err
+}
+
+// ActiveHalt returns the halt currently in effect, or nil if there is none.
+func ActiveHalt(tx *sql.Tx) (*Halt, error) {
+	row := tx.QueryRow(`SELECT id, reason, halted_at, cleared_at FROM halts WHERE cleared_at IS NULL ORDER BY id DESC LIMIT 1`)
+	h := &Halt{}
+	err := row.Scan(&h.ID, &h.Reason, &h.HaltedAt, &h.ClearedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
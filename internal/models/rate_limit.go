@@ -0,0 +1,43 @@
+package models
+
+import "database/sql"
+
+// RateLimitState is the persisted token count for one (identity, pot_id,
+// method) bucket. hooks.RateLimiter keeps the live count in memory and
+// periodically flushes it here via Save, so bucket levels survive a
+// restart approximately rather than resetting every deploy.
+type RateLimitState struct {
+	Identity  string  `json:"identity"`
+	PotID     int32   `json:"pot_id"`
+	Method    string  `json:"method"`
+	Tokens    float64 `json:"tokens"`
+	UpdatedAt int64   `json:"updated_at"`
+}
+
+// Save upserts the bucket keyed by (identity, pot_id, method).
+func (s *RateLimitState) Save(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		`INSERT INTO rate_limits (identity, pot_id, method, tokens, updated_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(identity, pot_id, method) DO UPDATE SET tokens = excluded.tokens, updated_at = excluded.updated_at`,
+		s.Identity, s.PotID, s.Method, s.Tokens, s.UpdatedAt,
+	)
+	return err
+}
+
+// RateLimitStateByKey loads a bucket's persisted token count, or nil if this
+// (identity, pot_id, method) has never been flushed before.
+func RateLimitStateByKey(tx *sql.Tx, identity string, potID int32, method string) (*RateLimitState, error) {
+	row := tx.QueryRow(
+		`SELECT identity, pot_id, method, tokens, updated_at FROM rate_limits WHERE identity = ? AND pot_id = ? AND method = ?`,
+		identity, potID, method,
+	)
+	s := &RateLimitState{}
+	err := row.Scan(&s.Identity, &s.PotID, &s.Method, &s.Tokens, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,128 @@
+package admin_provider
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/aspiration-labs/pyggpot/internal/models"
+	admin_service "github.com/aspiration-labs/pyggpot/rpc/go/admin"
+	"github.com/twitchtv/twirp"
+)
+
+func nowUnix() int64 {
+	return time.Now().Unix()
+}
+
+type adminServer struct {
+	DB *sql.DB
+}
+
+func New(db *sql.DB) *adminServer {
+	return &adminServer{
+		DB: db,
+	}
+}
+
+func (s *adminServer) SetHalt(ctx context.Context, request *admin_service.SetHaltRequest) (*admin_service.HaltStatus, error) {
+	if err := request.Validate(); err != nil {
+		return nil, twirp.InvalidArgumentError(err.Error(), "reason")
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	// The new halt supersedes the active one, which is cleared so that a
+	// later ClearHalt lifts the halt entirely.
+	prev, err := models.ActiveHalt(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, twirp.InternalError(err.Error())
+	}
+	if prev != nil {
+		prev.ClearedAt = sql.NullInt64{Int64: nowUnix(), Valid: true}
+		if err := prev.Save(tx); err != nil {
+			_ = tx.Rollback()
+			return nil, twirp.InternalError(err.Error())
+		}
+	}
+	halt := &models.Halt{Reason: request.Reason, HaltedAt: request.HaltAt}
+	if err := halt.Save(tx); err != nil {
+		_ = tx.Rollback()
+		return nil, twirp.InternalError(err.Error())
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+
+	return &admin_service.HaltStatus{Halted: halt.HaltedAt <= nowUnix(), Reason: halt.Reason, HaltAt: halt.HaltedAt}, nil
+}
+
+func (s *adminServer) ClearHalt(ctx context.Context, request *admin_service.ClearHaltRequest) (*admin_service.HaltStatus, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	halt, err := models.ActiveHalt(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, twirp.InternalError(err.Error())
+	}
+	if halt == nil {
+		_ = tx.Rollback()
+		return &admin_service.HaltStatus{Halted: false}, nil
+	}
+	halt.ClearedAt.Int64 = nowUnix()
+	halt.ClearedAt.Valid = true
+	if err := halt.Save(tx); err != nil {
+		_ = tx.Rollback()
+		return nil, twirp.InternalError(err.Error())
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+
+	return &admin_service.HaltStatus{Halted: false}, nil
+}
+
+func (s *adminServer) GetHalt(ctx context.Context, request *admin_service.GetHaltRequest) (*admin_service.HaltStatus, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	halt, err := models.ActiveHalt(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, twirp.InternalError(err.Error())
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	if halt == nil {
+		return &admin_service.HaltStatus{Halted: false}, nil
+	}
+	// Halted reflects whether the halt has actually taken effect yet, not
+	// just whether one is scheduled, so a caller can tell "halted now" from
+	// "halt is scheduled for HaltAt" before it arrives.
+	return &admin_service.HaltStatus{Halted: halt.HaltedAt <= nowUnix(), Reason: halt.Reason, HaltAt: halt.HaltedAt}, nil
+}
+
+// IsHalted satisfies hooks.HaltChecker so the halt middleware in main.go can
+// consult the same halts table without depending on the admin RPC types. A
+// halt row existing isn't enough by itself: HaltedAt may be scheduled for
+// the future, in which case mutating RPCs should keep working until it
+// arrives.
+func (s *adminServer) IsHalted(ctx context.Context) (bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	halt, err := models.ActiveHalt(tx)
+	if err != nil {
+		return false, err
+	}
+	return halt != nil && halt.HaltedAt <= nowUnix(), nil
+}
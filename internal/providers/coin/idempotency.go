@@ -0,0 +1,37 @@
+package coin_provider
+
+import (
+	"context"
+
+	"github.com/twitchtv/twirp"
+)
+
+// idempotencyKeyHeader and actorHeader are read from the inbound HTTP
+// request rather than added as proto fields, so that AddCoins/RemoveCoins
+// can support request replay detection and event attribution today without
+// waiting on a coin.proto change and regen.
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	actorHeader          = "X-Actor"
+)
+
+// requestIDFromContext returns the caller-supplied idempotency key for this
+// RPC, or "" if none was sent.
+func requestIDFromContext(ctx context.Context) string {
+	headers, ok := twirp.HTTPRequestHeaders(ctx)
+	if !ok {
+		return ""
+	}
+	return headers.Get(idempotencyKeyHeader)
+}
+
+// actorFromContext returns the caller identity attached to coin_events rows,
+// or "" if none was sent. It is self-reported by the caller and is not
+// checked against the identity AuthHooks attaches to the context.
+func actorFromContext(ctx context.Context) string {
+	headers, ok := twirp.HTTPRequestHeaders(ctx)
+	if !ok {
+		return ""
+	}
+	return headers.Get(actorHeader)
+}
@@ -0,0 +1,43 @@
+package coin_provider
+
+import (
+	"math/rand"
+
+	"github.com/aspiration-labs/pyggpot/internal/hooks"
+)
+
+// Option configures a coinServer built by New. The zero-value server (no
+// options passed) behaves exactly as before the CoinSelector refactor: a
+// uniform-by-count WeightedSelector over its own rand.Source, seeded from the
+// global math/rand source.
+type Option func(*coinServer)
+
+// WithSelector overrides the CoinSelector used by RemoveCoins. Defaults to
+// a uniform-by-count weighted selector over a rand.Source seeded from
+// math/rand's global source.
+func WithSelector(selector CoinSelector) Option {
+	return func(s *coinServer) {
+		s.selector = selector
+	}
+}
+
+// WithRandSource overrides the rand.Source backing the default selector. It
+// has no effect if WithSelector is also passed, since the supplied selector
+// owns its own source.
+func WithRandSource(source rand.Source) Option {
+	return func(s *coinServer) {
+		s.randSource = source
+	}
+}
+
+// WithRateLimiter enforces limiter's per-(identity, pot_id) buckets against
+// AddCoins/RemoveCoins using the actual PotId off the decoded request,
+// rather than a client-suppliable header. hooks.RateLimitHooks can only key
+// its own check on identity+method, since ServerHooks run before the body
+// is decoded; this is what restores the per-pot granularity for the two
+// RPCs expensive enough to matter.
+func WithRateLimiter(limiter *hooks.RateLimiter) Option {
+	return func(s *coinServer) {
+		s.limiter = limiter
+	}
+}
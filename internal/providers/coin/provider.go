@@ -5,31 +5,69 @@ import (
 	"database/sql"
 	"fmt"
 	"math/rand"
+	"time"
 
+	"github.com/aspiration-labs/pyggpot/internal/hooks"
 	"github.com/aspiration-labs/pyggpot/internal/models"
 	coin_service "github.com/aspiration-labs/pyggpot/rpc/go/coin"
 	"github.com/twitchtv/twirp"
 )
 
 type coinServer struct {
-	DB *sql.DB
+	DB         *sql.DB
+	selector   CoinSelector
+	randSource rand.Source
+	limiter    *hooks.RateLimiter
 }
 
-func New(db *sql.DB) *coinServer {
-	return &coinServer{
-		DB: db,
+// New builds a coin provider. With no options it reproduces the original
+// behavior: coins are drawn uniformly by remaining count using a rand.Source
+// seeded from math/rand's global source. Pass WithSelector to change the weighting strategy (see
+// NewWeightedSelector, NewBiasedSelector, NewDeterministicSelector) or
+// WithRandSource to keep the default weighting but control the RNG. Pass
+// WithRateLimiter to enforce per-pot rate limits on AddCoins/RemoveCoins.
+func New(db *sql.DB, opts ...Option) *coinServer {
+	s := &coinServer{DB: db}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if s.randSource == nil {
+		s.randSource = rand.NewSource(rand.Int63())
+	}
+	if s.selector == nil {
+		s.selector = NewWeightedSelector(nil, s.randSource)
+	}
+	return s
 }
 
 func (s *coinServer) AddCoins(ctx context.Context, request *coin_service.AddCoinsRequest) (*coin_service.CoinsListResponse, error) {
 	if err := request.Validate(); err != nil {
 		return nil, twirp.InvalidArgumentError(err.Error(), "")
 	}
+	if s.limiter != nil && !s.limiter.Allow(hooks.Identity(ctx), request.PotId, "AddCoins") {
+		return nil, twirp.NewError(twirp.ResourceExhausted, "rate limit exceeded")
+	}
 
 	tx, err := s.DB.Begin()
 	if err != nil {
 		return nil, twirp.InternalError(err.Error())
 	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	requestID := requestIDFromContext(ctx)
+	if dup, err := models.CoinEventExistsByRequestID(tx, requestID); err != nil {
+		return nil, twirp.InternalError(err.Error())
+	} else if dup {
+		return nil, twirp.NewError(twirp.AlreadyExists, "request already applied")
+	}
+
+	actor := actorFromContext(ctx)
+	now := time.Now().Unix()
 	for _, coin := range request.Coins {
 		fmt.Println(coin)
 		newCoin := models.Coin{
@@ -41,11 +79,23 @@ func (s *coinServer) AddCoins(ctx context.Context, request *coin_service.AddCoin
 		if err != nil {
 			return nil, twirp.InvalidArgumentError(err.Error(), "")
 		}
+		event := &models.CoinEvent{
+			PotID:     request.PotId,
+			Kind:      int32(coin.Kind),
+			Delta:     coin.Count,
+			Actor:     actor,
+			RequestID: requestID,
+			CreatedAt: now,
+		}
+		if err := event.Save(tx); err != nil {
+			return nil, twirp.InternalError(err.Error())
+		}
 	}
 	err = tx.Commit()
 	if err != nil {
 		return nil, twirp.NotFoundError(err.Error())
 	}
+	committed = true
 
 	return &coin_service.CoinsListResponse{
 		Coins: request.Coins,
@@ -56,6 +106,9 @@ func (s *coinServer) RemoveCoins(ctx context.Context, request *coin_service.Remo
 	if err := request.Validate(); err != nil {
 		return nil, twirp.InvalidArgumentError(err.Error(), "")
 	}
+	if s.limiter != nil && !s.limiter.Allow(hooks.Identity(ctx), request.PotId, "RemoveCoins") {
+		return nil, twirp.NewError(twirp.ResourceExhausted, "rate limit exceeded")
+	}
 
 	tx, err := s.DB.Begin()
 	if err != nil {
@@ -68,12 +121,23 @@ func (s *coinServer) RemoveCoins(ctx context.Context, request *coin_service.Remo
 		}
 	}()
 
+	requestID := requestIDFromContext(ctx)
+	if dup, err := models.CoinEventExistsByRequestID(tx, requestID); err != nil {
+		return nil, twirp.InternalError(err.Error())
+	} else if dup {
+		return nil, twirp.NewError(twirp.AlreadyExists, "request already applied")
+	}
+
 	coinsInPot, err := models.CoinsInPotsByPot_id(tx, int(request.PotId))
 	if err != nil {
 		return nil, twirp.InternalError(err.Error())
 	}
 
-	coinsRemoved := s.shakePot(coinsInPot, request.Count)
+	selector := s.selector
+	if seed, ok := ctx.Value(SeedKey).(int64); ok {
+		selector = NewDeterministicSelector(nil, seed)
+	}
+	coinsRemoved := selector.Select(coinsInPot, request.Count)
 
 	// now iterate over coinsInPot, deleting any with count = 0
 	// and updating the others
@@ -93,6 +157,22 @@ func (s *coinServer) RemoveCoins(ctx context.Context, request *coin_service.Remo
 		}
 	}
 
+	actor := actorFromContext(ctx)
+	now := time.Now().Unix()
+	for _, removed := range coinsRemoved {
+		event := &models.CoinEvent{
+			PotID:     request.PotId,
+			Kind:      int32(removed.Kind),
+			Delta:     -removed.Count,
+			Actor:     actor,
+			RequestID: requestID,
+			CreatedAt: now,
+		}
+		if err := event.Save(tx); err != nil {
+			return nil, twirp.InternalError(err.Error())
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, twirp.InternalError(err.Error())
@@ -104,91 +184,6 @@ func (s *coinServer) RemoveCoins(ctx context.Context, request *coin_service.Remo
 	}, nil
 }
 
-// shakePot jumps through a lot of hoops to ensure that it handles
-// multiple CoinsInPot with the same denomination, potentially with
-// count = 0.  It also relies on modifying the counts in the pot arg
-// as a side-effect, which I wouldn't ordinarily do or allow, but
-// there are limits to how much time I'm going to devote to this.
-// same goes for refactoring this into shorter functions
-//
-// Basic algorithm:
-//
-// compute count of each denomination of coins in pot and total count
-// generate random number between [0, total)
-// If number is between [0, gold_count), remove a gold coin.
-// If number is between [gold_count, gold_count + silver_count), remove silver coin
-// If number is >= gold_count + silver_count, remove bronze coin
-// so long as random number generator has even distribution, we will
-// end up removing coins proportionally to their count in the pot.
-//
-// Relies on side effect of modifying instances pointed to by pot in
-// order to communicate both the set of coins removed and the new state
-// of coins in pot, which is a code smell, but quick to implement
-func (s *coinServer) shakePot(pot []*models.CoinsInPot, count int32) []*coin_service.Coins {
-
-	// map of denomination to count
-	coinCounts := make(map[int32]int32, len(pot))
-	// map of denomination to array of models.CoinsInPot
-	coins := make(map[int32][]*models.CoinsInPot, len(pot))
-	// total coins in the pot
-	totalCoins := int32(0)
-	// populate our maps and compute totalCoins
-	for _, coin := range pot {
-		if coin == nil {
-			continue
-		}
-		coinCounts[coin.Denomination] += coin.CoinCount
-		coins[coin.Denomination] = append(coins[coin.Denomination], coin)
-		totalCoins += coin.CoinCount
-	}
-
-	results := make(map[int32]*coin_service.Coins, 3)
-	// we know the total number of coins in the pot and the number
-	// of each denomination. Now iterate, 'removing' coins until
-	// empty or count coins have been removed
-	for i := int32(0); i < count && totalCoins > 0; i++ {
-		// random int from [0, totalCoins)
-		idx := rand.Int31n(totalCoins)
-		// map idx to a kind
-		kind := int32(coin_service.Coins_UNKNOWN)
-		switch {
-		case idx < coinCounts[int32(coin_service.Coins_GOLD)]:
-			kind = int32(coin_service.Coins_GOLD)
-		case idx >= coinCounts[int32(coin_service.Coins_GOLD)] && idx < coinCounts[int32(coin_service.Coins_GOLD)]+coinCounts[int32(coin_service.Coins_SILVER)]:
-			kind = int32(coin_service.Coins_SILVER)
-		case idx >= coinCounts[int32(coin_service.Coins_GOLD)]+coinCounts[int32(coin_service.Coins_SILVER)]:
-			kind = int32(coin_service.Coins_BRONZE)
-		}
-
-		// now remove a coin of the specified kind from our data structures
-		totalCoins -= 1
-		coinCounts[kind] -= 1
-		// find non-empty coin to decrement from
-		for _, coin := range coins[kind] {
-			if coin.CoinCount > 0 {
-				coin.CoinCount -= 1
-				break
-			}
-		}
-		// and add a coin to the results map
-		removed, ok := results[kind]
-		if !ok {
-			results[kind] = &coin_service.Coins{
-				Kind:  coin_service.Coins_Kind(kind),
-				Count: 1,
-			}
-		} else {
-			removed.Count++
-		}
-	}
-	// convert map of Coins to slice of Coins
-	removedCoins := make([]*coin_service.Coins, 0, len(results))
-	for _, v := range results {
-		removedCoins = append(removedCoins, v)
-	}
-	return removedCoins
-}
-
 // ListCoins added to enable validation that RemoveCoins works correctly
 // without having to mock out a DB connection and otherwise build test
 // infrastructure. It was much faster to just cut and paste this together
@@ -0,0 +1,166 @@
+package coin_provider
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/aspiration-labs/pyggpot/internal/models"
+	coin_service "github.com/aspiration-labs/pyggpot/rpc/go/coin"
+)
+
+// CoinSelector picks coins out of a pot's current holdings. Implementations
+// decide both the odds of each denomination being drawn and the source of
+// randomness backing those odds, so callers can swap in deterministic or
+// value-biased behavior without touching RemoveCoins.
+type CoinSelector interface {
+	// Select removes up to count coins from pot, mutating the CoinCount
+	// fields in place exactly as the original shakePot did, and returns
+	// the coins that were drawn grouped by denomination.
+	Select(pot []*models.CoinsInPot, count int32) []*coin_service.Coins
+}
+
+// bucket is one (denomination, source row) pair with its remaining count.
+// Its weight, count * the configured per-coin weight for that denomination,
+// is recomputed on every draw.
+type bucket struct {
+	kind  int32
+	coin  *models.CoinsInPot
+	count int32
+}
+
+// weightedSelector reproduces the original cumulative-weight-array walk,
+// generalized to whatever denominations are present in the pot and to an
+// arbitrary per-kind weight map instead of the hardcoded GOLD/SILVER/BRONZE
+// switch. A nil or zero weight for a kind defaults to 1, so WeightedSelector
+// with an empty weight map reproduces the original uniform-by-count
+// behavior.
+type weightedSelector struct {
+	weights map[coin_service.Coins_Kind]int
+	source  rand.Source
+	mu      sync.Mutex
+}
+
+// NewWeightedSelector builds a CoinSelector whose odds of drawing a
+// denomination are proportional to weight(kind) * remaining-count(kind).
+// Passing a nil weights map (or one with no entry for a kind) weights that
+// kind as 1, which reproduces the pre-refactor uniform-by-count behavior.
+func NewWeightedSelector(weights map[coin_service.Coins_Kind]int, source rand.Source) CoinSelector {
+	return &weightedSelector{weights: weights, source: source}
+}
+
+// biasedWeightScale is the numerator used to turn a face value into an
+// inverse weight (see NewBiasedSelector). It only needs to be comfortably
+// larger than any realistic face value so integer division doesn't collapse
+// every denomination's weight down to the same value.
+const biasedWeightScale = 1 << 16
+
+// NewBiasedSelector builds a CoinSelector that weights each denomination
+// inversely to its face value, so that higher-denomination coins (e.g.
+// GOLD) are proportionally rarer to draw regardless of how many of them
+// remain in the pot. valueByKind entries of zero or less are treated as a
+// face value of 1. A kind with no entry gets weight 1, which makes it as
+// rare as a kind with a face value of biasedWeightScale.
+func NewBiasedSelector(valueByKind map[coin_service.Coins_Kind]int, source rand.Source) CoinSelector {
+	weights := make(map[coin_service.Coins_Kind]int, len(valueByKind))
+	for kind, value := range valueByKind {
+		if value <= 0 {
+			value = 1
+		}
+		weights[kind] = biasedWeightScale / value
+	}
+	return &weightedSelector{weights: weights, source: source}
+}
+
+func (s *weightedSelector) weightFor(kind coin_service.Coins_Kind) int {
+	if w, ok := s.weights[kind]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+func (s *weightedSelector) Select(pot []*models.CoinsInPot, count int32) []*coin_service.Coins {
+	// s.source is shared by every call on this selector (coinServer is a
+	// long-lived singleton serving concurrent requests), and math/rand's
+	// Source is documented as not safe for concurrent use, so rand.New(s.source)
+	// below needs exclusive access to it for the duration of one draw.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rng := rand.New(s.source)
+
+	buckets := make([]*bucket, 0, len(pot))
+	for _, coin := range pot {
+		if coin == nil || coin.CoinCount <= 0 {
+			continue
+		}
+		buckets = append(buckets, &bucket{kind: coin.Denomination, coin: coin, count: coin.CoinCount})
+	}
+
+	results := make(map[int32]*coin_service.Coins, len(buckets))
+	for i := int32(0); i < count && len(buckets) > 0; i++ {
+		cum := make([]int64, len(buckets))
+		var total int64
+		for i, b := range buckets {
+			total += int64(s.weightFor(coin_service.Coins_Kind(b.kind))) * int64(b.count)
+			cum[i] = total
+		}
+		if total <= 0 {
+			break
+		}
+		r := rng.Int63n(total)
+		idx := sort.Search(len(cum), func(i int) bool { return cum[i] > r })
+
+		b := buckets[idx]
+		b.coin.CoinCount--
+		b.count--
+
+		if removed, ok := results[b.kind]; ok {
+			removed.Count++
+		} else {
+			results[b.kind] = &coin_service.Coins{Kind: coin_service.Coins_Kind(b.kind), Count: 1}
+		}
+
+		if b.count <= 0 {
+			buckets = append(buckets[:idx], buckets[idx+1:]...)
+		}
+	}
+
+	removedCoins := make([]*coin_service.Coins, 0, len(results))
+	for _, v := range results {
+		removedCoins = append(removedCoins, v)
+	}
+	return removedCoins
+}
+
+// deterministicSelector wraps a weightedSelector pinned to a caller-supplied
+// seed, for tests that need reproducible draws.
+type deterministicSelector struct {
+	weighted CoinSelector
+}
+
+// NewDeterministicSelector builds a CoinSelector whose draws are fully
+// reproducible for a given pot state and seed. weights behaves as in
+// NewWeightedSelector.
+func NewDeterministicSelector(weights map[coin_service.Coins_Kind]int, seed int64) CoinSelector {
+	return &deterministicSelector{weighted: NewWeightedSelector(weights, rand.NewSource(seed))}
+}
+
+func (s *deterministicSelector) Select(pot []*models.CoinsInPot, count int32) []*coin_service.Coins {
+	return s.weighted.Select(pot, count)
+}
+
+// seedContextKey is the context key Go callers can set on ctx to pin a
+// single RemoveCoins draw to a given seed. There is no RPC-level way to set
+// this: the JSON Twirp dispatch that actually serves a remote call has no
+// field or header wired to it (unlike idempotencyKeyHeader/actorHeader in
+// idempotency.go), so it is only reachable by code calling the provider
+// in-process - tests, mainly.
+type seedContextKey struct{}
+
+// SeedKey is the context key under which RemoveCoins looks for a
+// caller-supplied int64 seed. When present, it takes precedence over the
+// selector configured on the coinServer for that one call. See
+// seedContextKey's comment: this is an in-process/test hook only, not
+// reachable by remote callers.
+var SeedKey = seedContextKey{}
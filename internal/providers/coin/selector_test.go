@@ -0,0 +1,161 @@
+package coin_provider
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/aspiration-labs/pyggpot/internal/models"
+	coin_service "github.com/aspiration-labs/pyggpot/rpc/go/coin"
+)
+
+func newTestSource() rand.Source {
+	return rand.NewSource(1)
+}
+
+func samplePot() []*models.CoinsInPot {
+	return []*models.CoinsInPot{
+		{ID: 1, Denomination: int32(coin_service.Coins_GOLD), CoinCount: 5},
+		{ID: 2, Denomination: int32(coin_service.Coins_SILVER), CoinCount: 10},
+	}
+}
+
+func totalRemoved(coins []*coin_service.Coins) int32 {
+	var total int32
+	for _, c := range coins {
+		total += c.Count
+	}
+	return total
+}
+
+func TestWeightedSelectorDrawsRequestedCount(t *testing.T) {
+	pot := samplePot()
+	selector := NewWeightedSelector(nil, newTestSource())
+
+	removed := selector.Select(pot, 4)
+	if got := totalRemoved(removed); got != 4 {
+		t.Fatalf("drew %d coins, want 4", got)
+	}
+
+	var remaining int32
+	for _, c := range pot {
+		remaining += c.CoinCount
+	}
+	if remaining != 15-4 {
+		t.Fatalf("pot has %d coins remaining, want %d", remaining, 15-4)
+	}
+}
+
+func TestWeightedSelectorStopsAtEmptyPot(t *testing.T) {
+	pot := samplePot()
+	selector := NewWeightedSelector(nil, newTestSource())
+
+	removed := selector.Select(pot, 1000)
+	if got := totalRemoved(removed); got != 15 {
+		t.Fatalf("drew %d coins from a 15-coin pot, want 15 (can't over-draw)", got)
+	}
+	for _, c := range pot {
+		if c.CoinCount != 0 {
+			t.Fatalf("expected every denomination to be fully drained, got %+v", c)
+		}
+	}
+}
+
+func TestWeightedSelectorZeroCount(t *testing.T) {
+	pot := samplePot()
+	selector := NewWeightedSelector(nil, newTestSource())
+
+	removed := selector.Select(pot, 0)
+	if len(removed) != 0 {
+		t.Fatalf("expected no coins drawn for count=0, got %v", removed)
+	}
+}
+
+func TestWeightedSelectorEmptyPot(t *testing.T) {
+	selector := NewWeightedSelector(nil, newTestSource())
+	if removed := selector.Select(nil, 3); len(removed) != 0 {
+		t.Fatalf("expected no coins drawn from an empty pot, got %v", removed)
+	}
+}
+
+func TestDeterministicSelectorIsReproducible(t *testing.T) {
+	potA := samplePot()
+	potB := samplePot()
+
+	a := NewDeterministicSelector(nil, 42).Select(potA, 6)
+	b := NewDeterministicSelector(nil, 42).Select(potB, 6)
+
+	if totalRemoved(a) != totalRemoved(b) {
+		t.Fatalf("same seed produced different draw totals: %d vs %d", totalRemoved(a), totalRemoved(b))
+	}
+	for _, kind := range []coin_service.Coins_Kind{coin_service.Coins_GOLD, coin_service.Coins_SILVER} {
+		var countA, countB int32
+		for _, c := range a {
+			if c.Kind == kind {
+				countA = c.Count
+			}
+		}
+		for _, c := range b {
+			if c.Kind == kind {
+				countB = c.Count
+			}
+		}
+		if countA != countB {
+			t.Fatalf("same seed drew different counts for kind %v: %d vs %d", kind, countA, countB)
+		}
+	}
+}
+
+// TestBiasedSelectorDrawsHighValueCoinsLessOften asserts the direction
+// NewBiasedSelector is documented to produce: GOLD is worth more than
+// SILVER, so across many draws from an evenly-stocked pot, GOLD should come
+// out less often, not more.
+func TestBiasedSelectorDrawsHighValueCoinsLessOften(t *testing.T) {
+	values := map[coin_service.Coins_Kind]int{
+		coin_service.Coins_GOLD:   25,
+		coin_service.Coins_SILVER: 5,
+	}
+	selector := NewBiasedSelector(values, newTestSource())
+
+	pot := []*models.CoinsInPot{
+		{ID: 1, Denomination: int32(coin_service.Coins_GOLD), CoinCount: 100000},
+		{ID: 2, Denomination: int32(coin_service.Coins_SILVER), CoinCount: 100000},
+	}
+
+	removed := selector.Select(pot, 10000)
+
+	var gold, silver int32
+	for _, c := range removed {
+		switch c.Kind {
+		case coin_service.Coins_GOLD:
+			gold = c.Count
+		case coin_service.Coins_SILVER:
+			silver = c.Count
+		}
+	}
+	if gold == 0 || silver == 0 {
+		t.Fatalf("expected both denominations to be drawn at least once, got gold=%d silver=%d", gold, silver)
+	}
+	if gold >= silver {
+		t.Fatalf("expected GOLD (higher face value) to be drawn less often than SILVER, got gold=%d silver=%d", gold, silver)
+	}
+}
+
+// TestWeightedSelectorConcurrentUse exercises Select from many goroutines
+// against one shared selector, the way coinServer's default selector is
+// actually used. It only fails under -race if the mutex guarding the
+// shared rand.Source is missing or wrong.
+func TestWeightedSelectorConcurrentUse(t *testing.T) {
+	selector := NewWeightedSelector(nil, newTestSource())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pot := samplePot()
+			selector.Select(pot, 3)
+		}()
+	}
+	wg.Wait()
+}
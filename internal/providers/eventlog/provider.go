@@ -0,0 +1,57 @@
+package eventlog_provider
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/aspiration-labs/pyggpot/internal/models"
+	eventlog_service "github.com/aspiration-labs/pyggpot/rpc/go/eventlog"
+	"github.com/twitchtv/twirp"
+)
+
+type eventLogServer struct {
+	DB *sql.DB
+}
+
+func New(db *sql.DB) *eventLogServer {
+	return &eventLogServer{
+		DB: db,
+	}
+}
+
+func (s *eventLogServer) ListEvents(ctx context.Context, request *eventlog_service.ListEventsRequest) (*eventlog_service.ListEventsResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, twirp.InvalidArgumentError(err.Error(), "")
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+
+	events, err := models.CoinEventsByPot_idSince(tx, int(request.PotId), int(request.SinceId))
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+
+	response := &eventlog_service.ListEventsResponse{
+		Events: make([]*eventlog_service.Event, 0, len(events)),
+	}
+	for _, e := range events {
+		response.Events = append(response.Events, &eventlog_service.Event{
+			Id:        e.ID,
+			PotId:     e.PotID,
+			Kind:      e.Kind,
+			Delta:     e.Delta,
+			Actor:     e.Actor,
+			RequestId: e.RequestID,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
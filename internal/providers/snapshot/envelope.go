@@ -0,0 +1,77 @@
+package snapshot_provider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+)
+
+// envelopeSchemaVersion is bumped whenever the fields of envelope change in
+// a way that breaks decoding an older blob. ImportSnapshot rejects anything
+// else outright rather than guessing at a migration.
+const envelopeSchemaVersion = 1
+
+// coinRow is one denomination's remaining count, the only per-pot state
+// ExportSnapshot needs to capture to let ImportSnapshot fully reconstruct
+// CoinsInPot without replaying coin_events.
+type coinRow struct {
+	Denomination int32
+	CoinCount    int32
+}
+
+// envelope is the versioned, checksummed blob ExportSnapshot hands back and
+// ImportSnapshot consumes. It is gob-encoded rather than hand-rolled binary
+// because the schema is simple and stable, and gob already gives us a
+// self-describing, version-tolerant wire format for free.
+type envelope struct {
+	SchemaVersion  uint32
+	SourceServerID string
+	EventCursor    int32
+	PotID          int32
+	Coins          []coinRow
+	Checksum       [32]byte
+}
+
+var (
+	errSchemaVersion = errors.New("snapshot schema version mismatch")
+	errChecksum      = errors.New("snapshot checksum mismatch")
+)
+
+// encodeEnvelope computes the payload checksum and gob-encodes the full
+// envelope, checksum included, into the bytes ExportSnapshot returns.
+func encodeEnvelope(e envelope) ([]byte, error) {
+	e.Checksum = checksumOf(e)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeEnvelope gob-decodes data and verifies its schema version and
+// checksum before handing it back, so ImportSnapshot never applies a
+// corrupted or incompatible blob.
+func decodeEnvelope(data []byte) (envelope, error) {
+	var e envelope
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return envelope{}, err
+	}
+	if e.SchemaVersion != envelopeSchemaVersion {
+		return envelope{}, errSchemaVersion
+	}
+	want := e.Checksum
+	if checksumOf(e) != want {
+		return envelope{}, errChecksum
+	}
+	return e, nil
+}
+
+// checksumOf hashes every field of e except Checksum itself, so the hash
+// can be verified against the value stored alongside it.
+func checksumOf(e envelope) [32]byte {
+	e.Checksum = [32]byte{}
+	var buf bytes.Buffer
+	_ = gob.NewEncoder(&buf).Encode(e)
+	return sha256.Sum256(buf.Bytes())
+}
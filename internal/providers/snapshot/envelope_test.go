@@ -0,0 +1,78 @@
+package snapshot_provider
+
+import "testing"
+
+func TestEncodeDecodeEnvelopeRoundTrip(t *testing.T) {
+	want := envelope{
+		SchemaVersion:  envelopeSchemaVersion,
+		SourceServerID: "server-1",
+		EventCursor:    42,
+		PotID:          7,
+		Coins: []coinRow{
+			{Denomination: 1, CoinCount: 10},
+			{Denomination: 2, CoinCount: 0},
+		},
+	}
+
+	data, err := encodeEnvelope(want)
+	if err != nil {
+		t.Fatalf("encodeEnvelope: %v", err)
+	}
+
+	got, err := decodeEnvelope(data)
+	if err != nil {
+		t.Fatalf("decodeEnvelope: %v", err)
+	}
+	if got.SourceServerID != want.SourceServerID || got.EventCursor != want.EventCursor ||
+		got.PotID != want.PotID || len(got.Coins) != len(want.Coins) {
+		t.Fatalf("decodeEnvelope round trip mismatch: got %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeEnvelopeEmptyPot(t *testing.T) {
+	data, err := encodeEnvelope(envelope{SchemaVersion: envelopeSchemaVersion, PotID: 1})
+	if err != nil {
+		t.Fatalf("encodeEnvelope: %v", err)
+	}
+	got, err := decodeEnvelope(data)
+	if err != nil {
+		t.Fatalf("decodeEnvelope: %v", err)
+	}
+	if len(got.Coins) != 0 {
+		t.Fatalf("expected no coin rows, got %v", got.Coins)
+	}
+}
+
+func TestDecodeEnvelopeRejectsWrongSchemaVersion(t *testing.T) {
+	data, err := encodeEnvelope(envelope{SchemaVersion: envelopeSchemaVersion + 1, PotID: 1})
+	if err != nil {
+		t.Fatalf("encodeEnvelope: %v", err)
+	}
+	if _, err := decodeEnvelope(data); err != errSchemaVersion {
+		t.Fatalf("decodeEnvelope error = %v, want errSchemaVersion", err)
+	}
+}
+
+func TestDecodeEnvelopeRejectsCorruptedChecksum(t *testing.T) {
+	data, err := encodeEnvelope(envelope{
+		SchemaVersion: envelopeSchemaVersion,
+		PotID:         1,
+		Coins:         []coinRow{{Denomination: 1, CoinCount: 5}},
+	})
+	if err != nil {
+		t.Fatalf("encodeEnvelope: %v", err)
+	}
+	data[len(data)-1] ^= 0xFF
+	// Flipping a trailing byte corrupts either the gob framing or the
+	// payload the checksum covers; either way decodeEnvelope must reject it
+	// rather than silently accepting a tampered blob.
+	if _, err := decodeEnvelope(data); err == nil {
+		t.Fatal("decodeEnvelope: expected an error for a corrupted blob, got nil")
+	}
+}
+
+func TestDecodeEnvelopeRejectsGarbage(t *testing.T) {
+	if _, err := decodeEnvelope([]byte("not a snapshot")); err == nil {
+		t.Fatal("decodeEnvelope: expected an error for garbage input, got nil")
+	}
+}
@@ -0,0 +1,130 @@
+package snapshot_provider
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/aspiration-labs/pyggpot/internal/models"
+	snapshot_service "github.com/aspiration-labs/pyggpot/rpc/go/snapshot"
+	"github.com/twitchtv/twirp"
+)
+
+type snapshotServer struct {
+	DB             *sql.DB
+	SourceServerID string
+}
+
+// New builds a snapshot provider. sourceServerID is stamped into every
+// exported envelope so an operator inspecting a blob (or ImportSnapshot,
+// eventually) can tell which instance it came from.
+func New(db *sql.DB, sourceServerID string) *snapshotServer {
+	return &snapshotServer{
+		DB:             db,
+		SourceServerID: sourceServerID,
+	}
+}
+
+func (s *snapshotServer) ExportSnapshot(ctx context.Context, request *snapshot_service.ExportSnapshotRequest) (*snapshot_service.ExportSnapshotResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, twirp.InvalidArgumentError(err.Error(), "")
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	coinsInPot, err := models.CoinsInPotsByPot_id(tx, int(request.PotId))
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+
+	cursor := request.AsOfEventId
+	if cursor == 0 {
+		cursor, err = models.LatestCoinEventID(tx, int(request.PotId))
+		if err != nil {
+			return nil, twirp.InternalError(err.Error())
+		}
+	}
+
+	rows := make([]coinRow, 0, len(coinsInPot))
+	for _, c := range coinsInPot {
+		rows = append(rows, coinRow{Denomination: c.Denomination, CoinCount: c.CoinCount})
+	}
+
+	data, err := encodeEnvelope(envelope{
+		SchemaVersion:  envelopeSchemaVersion,
+		SourceServerID: s.SourceServerID,
+		EventCursor:    cursor,
+		PotID:          request.PotId,
+		Coins:          rows,
+	})
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+
+	return &snapshot_service.ExportSnapshotResponse{Data: data}, nil
+}
+
+// ImportSnapshot overwrites the coin rows of request.PotId with the ones
+// the envelope carries. It does not create a fresh pot under a new ID:
+// that's the pot service's job, and out of scope here without a dependency
+// on internal/providers/pot. Callers migrating across instances create the
+// destination pot first and pass its id as request.PotId; the pot id
+// embedded in the envelope (env.PotID, the source's own id) is only ever
+// used to compute the export and is otherwise ignored here, so an import
+// can never collide with an unrelated local pot that happens to share the
+// source's numeric id.
+func (s *snapshotServer) ImportSnapshot(ctx context.Context, request *snapshot_service.ImportSnapshotRequest) (*snapshot_service.ImportSnapshotResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, twirp.InvalidArgumentError(err.Error(), "data")
+	}
+
+	env, err := decodeEnvelope(request.Data)
+	if err != nil {
+		return nil, twirp.InvalidArgumentError(err.Error(), "data")
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	existing, err := models.CoinsInPotsByPot_id(tx, int(request.PotId))
+	if err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	for _, c := range existing {
+		coin, err := models.CoinByID(tx, c.ID)
+		if err != nil {
+			return nil, twirp.InternalError(err.Error())
+		}
+		if err := coin.Delete(tx); err != nil {
+			return nil, twirp.InternalError(err.Error())
+		}
+	}
+	for _, row := range env.Coins {
+		coin := models.Coin{
+			PotID:        request.PotId,
+			Denomination: row.Denomination,
+			CoinCount:    row.CoinCount,
+		}
+		if err := coin.Save(tx); err != nil {
+			return nil, twirp.InternalError(err.Error())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, twirp.InternalError(err.Error())
+	}
+	committed = true
+
+	return &snapshot_service.ImportSnapshotResponse{PotId: request.PotId}, nil
+}
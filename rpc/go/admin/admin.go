@@ -0,0 +1,140 @@
+// Package admin is the Twirp service contract for the admin RPCs defined in
+// rpc/protobuf/admin.proto: SetHalt, ClearHalt, and GetHalt. It follows the
+// same shape as the generated coin and pot service packages (an interface,
+// request/response structs with Validate(), a PathPrefix constant, and an
+// http.Handler constructor) so that provider code and main.go wire it up the
+// same way they wire up CoinServer and PotServer.
+//
+// Unlike those, this file is hand-written, not protoc-gen-twirp output:
+// this checkout has no protoc/protoc-gen-twirp toolchain. Keep it in sync
+// with admin.proto by hand until that's regenerated for real.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+)
+
+// AdminPathPrefix is the base path this service's handler is mounted under.
+const AdminPathPrefix = "/twirp/admin.Admin/"
+
+// AdminServer is implemented by internal/providers/admin.
+type AdminServer interface {
+	SetHalt(ctx context.Context, request *SetHaltRequest) (*HaltStatus, error)
+	ClearHalt(ctx context.Context, request *ClearHaltRequest) (*HaltStatus, error)
+	GetHalt(ctx context.Context, request *GetHaltRequest) (*HaltStatus, error)
+}
+
+// SetHaltRequest schedules a halt. HaltAt is a unix-seconds timestamp; zero
+// means "effective immediately".
+type SetHaltRequest struct {
+	Reason string `json:"reason"`
+	HaltAt int64  `json:"halt_at"`
+}
+
+// Validate requires a Reason so halts show up meaningfully in an audit trail.
+func (r *SetHaltRequest) Validate() error {
+	if r == nil || strings.TrimSpace(r.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	return nil
+}
+
+// ClearHaltRequest lifts whatever halt is currently active. It has no
+// fields, but is a struct (rather than a bare RPC) to match the Twirp
+// convention of one message type per method.
+type ClearHaltRequest struct{}
+
+// Validate is a no-op; present for interface symmetry with the other requests.
+func (r *ClearHaltRequest) Validate() error { return nil }
+
+// GetHaltRequest has no fields; it just asks for current halt status.
+type GetHaltRequest struct{}
+
+// Validate is a no-op; present for interface symmetry with the other requests.
+func (r *GetHaltRequest) Validate() error { return nil }
+
+// HaltStatus is returned by all three RPCs and reflects the halt state after
+// the request was applied (or, for GetHalt, the current state).
+type HaltStatus struct {
+	Halted bool   `json:"halted"`
+	Reason string `json:"reason,omitempty"`
+	HaltAt int64  `json:"halt_at,omitempty"`
+}
+
+// NewAdminServer returns an http.Handler that dispatches JSON Twirp requests
+// to svc, running hooks the same way NewCoinServer and NewPotServer do.
+func NewAdminServer(svc AdminServer, hooks ...*twirp.ServerHooks) http.Handler {
+	return &adminServer{svc: svc, hooks: twirp.ChainHooks(hooks...)}
+}
+
+type adminServer struct {
+	svc   AdminServer
+	hooks *twirp.ServerHooks
+}
+
+func (s *adminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if s.hooks != nil {
+		ctx = ctxsetters.WithMethodName(ctx, strings.TrimPrefix(r.URL.Path, AdminPathPrefix))
+		if s.hooks.RequestReceived != nil {
+			var err error
+			if ctx, err = s.hooks.RequestReceived(ctx); err != nil {
+				s.writeError(w, err)
+				return
+			}
+		}
+		if s.hooks.RequestRouted != nil {
+			var err error
+			if ctx, err = s.hooks.RequestRouted(ctx); err != nil {
+				s.writeError(w, err)
+				return
+			}
+		}
+	}
+
+	var resp *HaltStatus
+	var err error
+	switch strings.TrimPrefix(r.URL.Path, AdminPathPrefix) {
+	case "SetHalt":
+		req := &SetHaltRequest{}
+		if err = json.NewDecoder(r.Body).Decode(req); err == nil {
+			if err = req.Validate(); err == nil {
+				resp, err = s.svc.SetHalt(ctx, req)
+			} else {
+				err = twirp.InvalidArgumentError(err.Error(), "reason")
+			}
+		}
+	case "ClearHalt":
+		req := &ClearHaltRequest{}
+		resp, err = s.svc.ClearHalt(ctx, req)
+	case "GetHalt":
+		req := &GetHaltRequest{}
+		resp, err = s.svc.GetHalt(ctx, req)
+	default:
+		err = twirp.NotFoundError("no such method")
+	}
+
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *adminServer) writeError(w http.ResponseWriter, err error) {
+	twerr, ok := err.(twirp.Error)
+	if !ok {
+		twerr = twirp.InternalError(err.Error())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(twirp.ServerHTTPStatusFromErrorCode(twerr.Code()))
+	_ = json.NewEncoder(w).Encode(map[string]string{"code": string(twerr.Code()), "msg": twerr.Msg()})
+}
@@ -0,0 +1,114 @@
+// Package eventlog is the Twirp service contract for tailing coin_events,
+// built the same way rpc/go/admin is: an interface, plain request/response
+// structs with Validate(), a PathPrefix constant, and an http.Handler
+// constructor mirroring the generated coin and pot service packages.
+//
+// Like rpc/go/admin, this file is hand-written, not protoc-gen-twirp
+// output: this checkout has no protoc/protoc-gen-twirp toolchain. Keep it
+// in sync with eventlog.proto by hand until that's regenerated for real.
+package eventlog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+)
+
+// EventLogPathPrefix is the base path this service's handler is mounted under.
+const EventLogPathPrefix = "/twirp/eventlog.EventLog/"
+
+// EventLogServer is implemented by internal/providers/eventlog.
+type EventLogServer interface {
+	ListEvents(ctx context.Context, request *ListEventsRequest) (*ListEventsResponse, error)
+}
+
+// ListEventsRequest asks for pot_id's events with id > SinceId, letting a
+// client tail the log by passing back the last id it saw.
+type ListEventsRequest struct {
+	PotId   int32 `json:"pot_id"`
+	SinceId int32 `json:"since_id"`
+}
+
+// Validate is a no-op; zero values mean "this pot, from the start".
+func (r *ListEventsRequest) Validate() error { return nil }
+
+// Event is one append-only coin movement.
+type Event struct {
+	Id        int32  `json:"id"`
+	PotId     int32  `json:"pot_id"`
+	Kind      int32  `json:"kind"`
+	Delta     int32  `json:"delta"`
+	Actor     string `json:"actor"`
+	RequestId string `json:"request_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// ListEventsResponse is ordered oldest-first, same as the underlying query.
+type ListEventsResponse struct {
+	Events []*Event `json:"events"`
+}
+
+// NewEventLogServer returns an http.Handler that dispatches JSON Twirp
+// requests to svc, running hooks the same way NewCoinServer does.
+func NewEventLogServer(svc EventLogServer, hooks ...*twirp.ServerHooks) http.Handler {
+	return &eventLogServer{svc: svc, hooks: twirp.ChainHooks(hooks...)}
+}
+
+type eventLogServer struct {
+	svc   EventLogServer
+	hooks *twirp.ServerHooks
+}
+
+func (s *eventLogServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	method := strings.TrimPrefix(r.URL.Path, EventLogPathPrefix)
+	if s.hooks != nil {
+		ctx = ctxsetters.WithMethodName(ctx, method)
+		if s.hooks.RequestReceived != nil {
+			var err error
+			if ctx, err = s.hooks.RequestReceived(ctx); err != nil {
+				s.writeError(w, err)
+				return
+			}
+		}
+		if s.hooks.RequestRouted != nil {
+			var err error
+			if ctx, err = s.hooks.RequestRouted(ctx); err != nil {
+				s.writeError(w, err)
+				return
+			}
+		}
+	}
+
+	if method != "ListEvents" {
+		s.writeError(w, twirp.NotFoundError("no such method"))
+		return
+	}
+
+	req := &ListEventsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		s.writeError(w, twirp.InvalidArgumentError(err.Error(), ""))
+		return
+	}
+	resp, err := s.svc.ListEvents(ctx, req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *eventLogServer) writeError(w http.ResponseWriter, err error) {
+	twerr, ok := err.(twirp.Error)
+	if !ok {
+		twerr = twirp.InternalError(err.Error())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(twirp.ServerHTTPStatusFromErrorCode(twerr.Code()))
+	_ = json.NewEncoder(w).Encode(map[string]string{"code": string(twerr.Code()), "msg": twerr.Msg()})
+}
@@ -0,0 +1,140 @@
+// Package sidecar is the Twirp service contract for attaching and reading
+// the optional coin attributes (mint year, provenance, serial numbers)
+// described in internal/models.CoinSidecar, built the same way rpc/go/admin
+// and rpc/go/eventlog are.
+//
+// Like those, this file is hand-written, not protoc-gen-twirp output: this
+// checkout has no protoc/protoc-gen-twirp toolchain. Keep it in sync with
+// sidecar.proto by hand until that's regenerated for real.
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+)
+
+// SidecarPathPrefix is the base path this service's handler is mounted under.
+const SidecarPathPrefix = "/twirp/sidecar.Sidecar/"
+
+// SidecarServer is implemented by internal/providers/sidecar.
+type SidecarServer interface {
+	SetSidecar(ctx context.Context, request *SetSidecarRequest) (*Sidecar, error)
+	GetSidecars(ctx context.Context, request *GetSidecarsRequest) (*GetSidecarsResponse, error)
+}
+
+// SetSidecarRequest attaches sidecar attributes to an existing coin row.
+type SetSidecarRequest struct {
+	CoinId        int32    `json:"coin_id"`
+	MintYear      int32    `json:"mint_year"`
+	Provenance    string   `json:"provenance"`
+	SerialNumbers []string `json:"serial_numbers"`
+}
+
+// Validate requires a coin id; denomination eligibility is checked by the
+// provider, which has to load the coin row anyway.
+func (r *SetSidecarRequest) Validate() error {
+	if r == nil || r.CoinId <= 0 {
+		return errors.New("coin_id is required")
+	}
+	return nil
+}
+
+// Sidecar is the attribute bundle for one coin row.
+type Sidecar struct {
+	CoinId        int32    `json:"coin_id"`
+	MintYear      int32    `json:"mint_year"`
+	Provenance    string   `json:"provenance"`
+	SerialNumbers []string `json:"serial_numbers"`
+}
+
+// GetSidecarsRequest batches a lookup across coin rows so a caller who wants
+// sidecar data for a whole pot's worth of coins can do it in one round trip.
+type GetSidecarsRequest struct {
+	CoinIds []int32 `json:"coin_ids"`
+}
+
+// Validate is a no-op; an empty CoinIds list just returns no sidecars.
+func (r *GetSidecarsRequest) Validate() error { return nil }
+
+// GetSidecarsResponse omits any coin id that has no sidecar row, rather than
+// padding the list with empty entries.
+type GetSidecarsResponse struct {
+	Sidecars []*Sidecar `json:"sidecars"`
+}
+
+// NewSidecarServer returns an http.Handler that dispatches JSON Twirp
+// requests to svc, running hooks the same way NewCoinServer does.
+func NewSidecarServer(svc SidecarServer, hooks ...*twirp.ServerHooks) http.Handler {
+	return &sidecarServer{svc: svc, hooks: twirp.ChainHooks(hooks...)}
+}
+
+type sidecarServer struct {
+	svc   SidecarServer
+	hooks *twirp.ServerHooks
+}
+
+func (s *sidecarServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	method := strings.TrimPrefix(r.URL.Path, SidecarPathPrefix)
+	if s.hooks != nil {
+		ctx = ctxsetters.WithMethodName(ctx, method)
+		if s.hooks.RequestReceived != nil {
+			var err error
+			if ctx, err = s.hooks.RequestReceived(ctx); err != nil {
+				s.writeError(w, err)
+				return
+			}
+		}
+		if s.hooks.RequestRouted != nil {
+			var err error
+			if ctx, err = s.hooks.RequestRouted(ctx); err != nil {
+				s.writeError(w, err)
+				return
+			}
+		}
+	}
+
+	var body interface{}
+	var err error
+	switch method {
+	case "SetSidecar":
+		req := &SetSidecarRequest{}
+		if err = json.NewDecoder(r.Body).Decode(req); err == nil {
+			if err = req.Validate(); err == nil {
+				body, err = s.svc.SetSidecar(ctx, req)
+			} else {
+				err = twirp.InvalidArgumentError(err.Error(), "coin_id")
+			}
+		}
+	case "GetSidecars":
+		req := &GetSidecarsRequest{}
+		if err = json.NewDecoder(r.Body).Decode(req); err == nil {
+			body, err = s.svc.GetSidecars(ctx, req)
+		}
+	default:
+		err = twirp.NotFoundError("no such method")
+	}
+
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (s *sidecarServer) writeError(w http.ResponseWriter, err error) {
+	twerr, ok := err.(twirp.Error)
+	if !ok {
+		twerr = twirp.InternalError(err.Error())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(twirp.ServerHTTPStatusFromErrorCode(twerr.Code()))
+	_ = json.NewEncoder(w).Encode(map[string]string{"code": string(twerr.Code()), "msg": twerr.Msg()})
+}